@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"budget-app/backend/database"
+	"budget-app/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var roleCollection *mongo.Collection = database.OpenCollection(database.Client, "roles")
+
+// Seeds the "admin" role on startup if it doesn't already exist. Without
+// this, nothing ever carries "roles:manage" and the role-management routes
+// (and GetUsers, which requires "users:read") would be permanently
+// unreachable - see SignUp's FIRST_ADMIN_EMAIL handling for how an account
+// actually gets this role.
+func init() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := roleCollection.CountDocuments(ctx, bson.M{"name": "admin"})
+	if err != nil {
+		log.Println("failed to check for seeded admin role:", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	admin := models.Role{
+		ID:   primitive.NewObjectID(),
+		Name: "admin",
+		Permissions: []string{
+			models.PermissionUsersRead,
+			models.PermissionUsersWrite,
+			models.PermissionRolesManage,
+		},
+	}
+	if _, err := roleCollection.InsertOne(ctx, admin); err != nil {
+		log.Println("failed to seed admin role:", err)
+	}
+}
+
+// CreateRole defines a new named bundle of permissions.
+func CreateRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var role models.Role
+		if err := c.BindJSON(&role); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(role); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		role.ID = primitive.NewObjectID()
+
+		if _, err := roleCollection.InsertOne(ctx, role); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating role"})
+			return
+		}
+
+		c.JSON(http.StatusOK, role)
+	}
+}
+
+// ListRoles returns every defined role and its permission set.
+func ListRoles() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		cursor, err := roleCollection.Find(ctx, bson.M{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing roles"})
+			return
+		}
+
+		var roles []models.Role
+		if err := cursor.All(ctx, &roles); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing roles"})
+			return
+		}
+
+		c.JSON(http.StatusOK, roles)
+	}
+}
+
+// AssignRoleInput is the body accepted by AssignRole and RevokeRole.
+type AssignRoleInput struct {
+	User_ID string `json:"user_id" validate:"required"`
+	Role    string `json:"role" validate:"required"`
+}
+
+// AssignRole grants a role to a user; a role the user already holds is left
+// alone. The user's current tokens keep whatever permissions they were
+// issued with until they next sign in or refresh.
+func AssignRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var input AssignRoleInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": input.User_ID}, bson.M{"$addToSet": bson.M{"roles": input.Role}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error assigning role"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Role assigned"})
+	}
+}
+
+// RevokeRole removes a role from a user.
+func RevokeRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var input AssignRoleInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": input.User_ID}, bson.M{"$pull": bson.M{"roles": input.Role}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error revoking role"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Role revoked"})
+	}
+}
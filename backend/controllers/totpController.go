@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	helper "budget-app/backend/helpers"
+	"budget-app/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+// EnrollTOTPResponse carries the secret (for manual entry / QR generation on
+// the client) and the one-time recovery codes. Neither is recoverable later.
+type EnrollTOTPResponse struct {
+	Secret        string   `json:"secret"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnrollTOTPInput is the body accepted by EnrollTOTP. Password is only
+// required when the caller already has TOTP enabled, since (re-)enrolling
+// overwrites the secret and recovery codes a stolen access token could
+// otherwise use to silently strip existing MFA.
+type EnrollTOTPInput struct {
+	Password string `json:"password"`
+}
+
+// EnrollTOTP generates a new TOTP secret and recovery codes for the caller
+// and stores them unconfirmed - TOTP_Enabled only flips to true once
+// ConfirmTOTP verifies the user actually has the secret loaded. If TOTP is
+// already enabled, the account password must be re-verified first.
+func EnrollTOTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId := c.GetString("uid")
+
+		var input EnrollTOTPInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var foundUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&foundUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			return
+		}
+
+		if foundUser.TOTP_Enabled {
+			if input.Password == "" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "password required to re-enroll TOTP"})
+				return
+			}
+
+			passwordIsValid, msg := VerifyPassword(input.Password, *foundUser.Password)
+			if !passwordIsValid {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+				return
+			}
+		}
+
+		secret, err := helper.GenerateTOTPSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating TOTP secret"})
+			return
+		}
+
+		encryptedSecret, err := helper.EncryptTOTPSecret(secret)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating TOTP secret"})
+			return
+		}
+
+		recoveryCodes, err := helper.GenerateRecoveryCodes(recoveryCodeCount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating recovery codes"})
+			return
+		}
+
+		hashedCodes := make([]string, len(recoveryCodes))
+		for i, code := range recoveryCodes {
+			hashedCodes[i] = HashPassword(code)
+		}
+
+		_, err = userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": bson.M{
+			"totp_secret":    encryptedSecret,
+			"totp_enabled":   false,
+			"recovery_codes": hashedCodes,
+		}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error enrolling TOTP"})
+			return
+		}
+
+		c.JSON(http.StatusOK, EnrollTOTPResponse{Secret: secret, RecoveryCodes: recoveryCodes})
+	}
+}
+
+// ConfirmTOTPInput is the body accepted by ConfirmTOTP.
+type ConfirmTOTPInput struct {
+	Code string `json:"code" validate:"required,len=6"`
+}
+
+// ConfirmTOTP proves the caller's authenticator app is correctly configured
+// before TOTP becomes mandatory on SignIn.
+func ConfirmTOTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId := c.GetString("uid")
+
+		var input ConfirmTOTPInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		var foundUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&foundUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			return
+		}
+
+		if foundUser.TOTP_Secret == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "TOTP has not been enrolled"})
+			return
+		}
+
+		secret, err := helper.DecryptTOTPSecret(foundUser.TOTP_Secret)
+		if err != nil || !helper.ValidateTOTPCode(secret, input.Code) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid TOTP code"})
+			return
+		}
+
+		_, err = userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": bson.M{"totp_enabled": true}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error confirming TOTP"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "TOTP enabled"})
+	}
+}
+
+// DisableTOTPInput is the body accepted by DisableTOTP.
+type DisableTOTPInput struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// DisableTOTP turns off TOTP after re-verifying the account password, and
+// wipes the stored secret and recovery codes.
+func DisableTOTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId := c.GetString("uid")
+
+		var input DisableTOTPInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		var foundUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&foundUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			return
+		}
+
+		passwordIsValid, msg := VerifyPassword(input.Password, *foundUser.Password)
+		if !passwordIsValid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": bson.M{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"recovery_codes": []string{},
+		}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error disabling TOTP"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "TOTP disabled"})
+	}
+}
+
+// SignInVerifyTOTPInput is the body accepted by SignInVerifyTOTP.
+type SignInVerifyTOTPInput struct {
+	ChallengeToken string `json:"mfa_challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,len=6"`
+}
+
+// SignInVerifyTOTP redeems the mfa_challenge_token SignIn issued, along with
+// a current TOTP code, for a full access+refresh pair.
+func SignInVerifyTOTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input SignInVerifyTOTPInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		challenge, err := loadMFAChallenge(input.ChallengeToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		secret, err := helper.DecryptTOTPSecret(challenge.user.TOTP_Secret)
+		if err != nil || !helper.ValidateTOTPCode(secret, input.Code) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid TOTP code"})
+			return
+		}
+
+		if err := challenge.revoke(); err != nil {
+			log.Panic(err)
+		}
+
+		issueTokensAfterMFA(c, challenge.user)
+	}
+}
+
+// SignInVerifyRecoveryInput is the body accepted by SignInVerifyRecovery.
+type SignInVerifyRecoveryInput struct {
+	ChallengeToken string `json:"mfa_challenge_token" validate:"required"`
+	RecoveryCode   string `json:"recovery_code" validate:"required"`
+}
+
+// SignInVerifyRecovery redeems the mfa_challenge_token along with a single-use
+// recovery code, for callers who have lost their authenticator device. The
+// code is invalidated immediately so it can't be reused.
+func SignInVerifyRecovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var input SignInVerifyRecoveryInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		challenge, err := loadMFAChallenge(input.ChallengeToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		matchedIndex := -1
+		for i, hashed := range challenge.user.Recovery_Codes {
+			if bcrypt.CompareHashAndPassword([]byte(hashed), []byte(input.RecoveryCode)) == nil {
+				matchedIndex = i
+				break
+			}
+		}
+
+		if matchedIndex == -1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid recovery code"})
+			return
+		}
+
+		if err := challenge.revoke(); err != nil {
+			log.Panic(err)
+		}
+
+		remaining := append(challenge.user.Recovery_Codes[:matchedIndex], challenge.user.Recovery_Codes[matchedIndex+1:]...)
+		_, err = userCollection.UpdateOne(ctx, bson.M{"user_id": challenge.user.User_ID}, bson.M{"$set": bson.M{"recovery_codes": remaining}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error redeeming recovery code"})
+			return
+		}
+
+		issueTokensAfterMFA(c, challenge.user)
+	}
+}
+
+// mfaChallenge is a validated-but-not-yet-consumed mfa_challenge_token: the
+// user it was issued for, plus enough of its claims to revoke it once the
+// second factor checks out. Keeping revocation separate from loading means a
+// mistyped code doesn't burn the challenge token - the caller gets to retry
+// until it actually expires.
+type mfaChallenge struct {
+	user      models.User
+	claimId   string
+	expiresAt time.Time
+}
+
+// revoke consumes the challenge token so it can't be redeemed again.
+func (m mfaChallenge) revoke() error {
+	return helper.RevokeToken(m.claimId, m.user.User_ID, m.expiresAt)
+}
+
+// loadMFAChallenge validates an mfa_challenge_token and loads the user it was
+// issued for, failing closed on any expired/revoked/malformed token. It does
+// not consume the token - callers must verify the second factor and call
+// mfaChallenge.revoke() themselves once it succeeds.
+func loadMFAChallenge(challengeToken string) (mfaChallenge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	claims, msg := helper.ValidateToken(challengeToken)
+	if msg != "" {
+		return mfaChallenge{}, errors.New(msg)
+	}
+
+	if !claims.MFA {
+		return mfaChallenge{}, errors.New("not an MFA challenge token")
+	}
+
+	if helper.IsTokenRevoked(claims.Id) {
+		return mfaChallenge{}, errors.New("challenge token has been revoked")
+	}
+
+	var foundUser models.User
+	if err := userCollection.FindOne(ctx, bson.M{"user_id": claims.Uid}).Decode(&foundUser); err != nil {
+		return mfaChallenge{}, errors.New("user not found")
+	}
+
+	return mfaChallenge{user: foundUser, claimId: claims.Id, expiresAt: time.Unix(claims.ExpiresAt, 0)}, nil
+}
+
+// issueTokensAfterMFA mints the real access+refresh pair once the second
+// factor has been verified, mirroring the non-MFA tail of SignIn.
+func issueTokensAfterMFA(c *gin.Context, foundUser models.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	permissions, err := helper.ResolvePermissions(foundUser.Roles)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error signing in"})
+		return
+	}
+
+	token, refreshToken, _ := helper.GenerateAllTokens(*foundUser.Email, *foundUser.Name, *foundUser.User_Type, foundUser.User_ID, foundUser.Roles, permissions)
+	helper.UpdateAllTokens(token, refreshToken, foundUser.User_ID)
+
+	if err := userCollection.FindOne(ctx, bson.M{"user_id": foundUser.User_ID}).Decode(&foundUser); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, foundUser)
+}
@@ -5,9 +5,18 @@ import (
 	helper "budget-app/backend/helpers"
 	"budget-app/backend/models"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,11 +24,80 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
+var passwordResetCollection *mongo.Collection = database.OpenCollection(database.Client, "password_resets")
 var validate = validator.New()
+var mailer helper.Mailer = helper.NewSMTPMailer()
+
+// How long a password reset token stays redeemable. Overridable via
+// PASSWORD_RESET_MINUTES.
+func passwordResetTokenTTL() time.Duration {
+	if raw := os.Getenv("PASSWORD_RESET_MINUTES"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return time.Duration(n) * time.Minute
+		}
+	}
+	return 30 * time.Minute
+}
+
+// generateResetToken returns a high-entropy, URL-safe token to hand to the
+// user and its SHA-256 hash to store instead of the raw value.
+func generateResetToken() (token string, tokenHash string, err error) {
+	raw := make([]byte, 32)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	token = hex.EncodeToString(raw)
+	sum := sha256.Sum256([]byte(token))
+	tokenHash = hex.EncodeToString(sum[:])
+	return token, tokenHash, nil
+}
+
+func init() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := userCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"email": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"username": 1}, Options: options.Index().SetUnique(true)},
+	})
+	if err != nil {
+		log.Println("failed to create user indexes:", err)
+	}
+}
+
+// Consecutive VerifyPassword failures allowed before an account is locked out.
+// Overridable via MAX_FAILED_LOGIN_ATTEMPTS for environments that want a tighter policy.
+func maxFailedLoginAttempts() int {
+	if raw := os.Getenv("MAX_FAILED_LOGIN_ATTEMPTS"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 5
+}
+
+// How long an account stays locked once maxFailedLoginAttempts is reached.
+// Overridable via ACCOUNT_LOCKOUT_MINUTES.
+func accountLockoutDuration() time.Duration {
+	if raw := os.Getenv("ACCOUNT_LOCKOUT_MINUTES"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return time.Duration(n) * time.Minute
+		}
+	}
+	return 15 * time.Minute
+}
+
+// SignInInput lets callers authenticate with either their email or their username.
+type SignInInput struct {
+	Identifier string `json:"identifier" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+}
 
 func HashPassword(password string) string {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
@@ -63,6 +141,7 @@ func SignUp() gin.HandlerFunc {
 		// validationErr will be nil if there are NO ERRORS
 		if validationErr := validate.Struct(user); validationErr != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
 		}
 
 		// Recall that BSON is Binary JSON, MongoDB stores documents in a binary representation known as BSON
@@ -79,11 +158,44 @@ func SignUp() gin.HandlerFunc {
 		// If there exists any other users with the same email, then return an error...
 		if count > 0 {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "This email already exists!"})
+			return
+		}
+
+		usernameCount, err := userCollection.CountDocuments(ctx, bson.M{"username": user.Username})
+		if err != nil {
+			log.Panic(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error whilst signing up..."})
+		}
+
+		if usernameCount > 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "This username already exists!"})
+			return
 		}
 
 		user.ID = primitive.NewObjectID()
 		user.User_ID = user.ID.Hex()
-		token, refreshToken, _ := helper.GenerateAllTokens(*user.Email, *user.Name, *user.User_Type, user.User_ID)
+		user.Created_at = time.Now()
+		user.Updated_at = time.Now()
+		// Roles are never taken from the request body - new accounts always
+		// start as plain "user" and gain more via AssignRole.
+		user.Roles = []string{"user"}
+
+		// FIRST_ADMIN_EMAIL lets an operator bootstrap the very first admin
+		// account out-of-band, since "roles:manage" is otherwise unreachable
+		// (see roleController.go's seeded "admin" role). Every admin grant
+		// after that one should go through AssignRole instead.
+		if adminEmail := os.Getenv("FIRST_ADMIN_EMAIL"); adminEmail != "" && strings.EqualFold(adminEmail, *user.Email) {
+			user.Roles = append(user.Roles, "admin")
+		}
+
+		permissions, err := helper.ResolvePermissions(user.Roles)
+		if err != nil {
+			log.Panic(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error whilst signing up..."})
+			return
+		}
+
+		token, refreshToken, _ := helper.GenerateAllTokens(*user.Email, *user.Name, *user.User_Type, user.User_ID, user.Roles, permissions)
 		user.Token = &token
 		user.Refresh_Token = &refreshToken
 
@@ -104,31 +216,65 @@ func SignIn() gin.HandlerFunc {
 		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		var user models.User
+		var input SignInInput
 		var foundUser models.User
 
-		if err := c.BindJSON(&user); err != nil {
+		if err := c.BindJSON(&input); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		err := userCollection.FindOne(ctx, bson.M{"email": user.Email}).Decode(&foundUser)
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		err := userCollection.FindOne(ctx, bson.M{"$or": []bson.M{
+			{"email": input.Identifier},
+			{"username": input.Identifier},
+		}}).Decode(&foundUser)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Email or Password is incorrect"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Email/username or password is incorrect"})
+			return
+		}
+
+		if !foundUser.Locked_Until.IsZero() && time.Now().Before(foundUser.Locked_Until) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Account locked due to too many failed login attempts, try again later"})
 			return
 		}
 
-		passwordIsValid, msg := VerifyPassword(*user.Password, *foundUser.Password)
+		passwordIsValid, msg := VerifyPassword(input.Password, *foundUser.Password)
 
 		if !passwordIsValid {
+			recordFailedLogin(ctx, foundUser.User_ID, foundUser.Failed_Login_Count)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 			return
 		}
 
 		if foundUser.Email == nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			return
+		}
+
+		resetFailedLogins(ctx, foundUser.User_ID)
+
+		if foundUser.TOTP_Enabled {
+			challengeToken, err := helper.GenerateMFAChallengeToken(foundUser.User_ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error signing in"})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"mfa_required": true, "mfa_challenge_token": challengeToken})
+			return
 		}
-		token, refreshToken, _ := helper.GenerateAllTokens(*foundUser.Email, *foundUser.Name, *foundUser.User_Type, foundUser.User_ID)
+
+		permissions, err := helper.ResolvePermissions(foundUser.Roles)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error signing in"})
+			return
+		}
+
+		token, refreshToken, _ := helper.GenerateAllTokens(*foundUser.Email, *foundUser.Name, *foundUser.User_Type, foundUser.User_ID, foundUser.Roles, permissions)
 		helper.UpdateAllTokens(token, refreshToken, foundUser.User_ID)
 		err = userCollection.FindOne(ctx, bson.M{"user_id": foundUser.User_ID}).Decode(&foundUser)
 
@@ -141,60 +287,541 @@ func SignIn() gin.HandlerFunc {
 	}
 }
 
-func GetUsers() gin.HandlerFunc {
+// RefreshTokenInput is the body accepted by RefreshToken: the refresh token
+// issued by the most recent SignIn/SignUp/RefreshToken call.
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshToken redeems a still-valid refresh token for a new access+refresh
+// pair, rotating the stored refresh token so the redeemed one cannot be used
+// again (single-use rotation) and revoking its jti outright.
+func RefreshToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var input RefreshTokenInput
+		if err := c.BindJSON(&input); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		claims, msg := helper.ValidateToken(input.RefreshToken)
+		if msg != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		if helper.IsTokenRevoked(claims.Id) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token has been revoked"})
+			return
+		}
+
+		var foundUser models.User
+		err := userCollection.FindOne(ctx, bson.M{"user_id": claims.Uid}).Decode(&foundUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+
+		if foundUser.Refresh_Token == nil || *foundUser.Refresh_Token != input.RefreshToken {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token has already been rotated"})
+			return
+		}
+
+		permissions, err := helper.ResolvePermissions(foundUser.Roles)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error refreshing token"})
+			return
+		}
+
+		newToken, newRefreshToken, _ := helper.GenerateAllTokens(*foundUser.Email, *foundUser.Name, *foundUser.User_Type, foundUser.User_ID, foundUser.Roles, permissions)
+		helper.UpdateAllTokens(newToken, newRefreshToken, foundUser.User_ID)
+
+		if err := helper.RevokeToken(claims.Id, foundUser.User_ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			log.Panic(err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"token": newToken, "refresh_token": newRefreshToken})
+	}
+}
+
+// SignOut revokes the caller's current access token and clears their stored
+// refresh token so neither can authenticate again before expiry.
+func SignOut() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-		if err != nil || recordPerPage < 1 {
-			recordPerPage = 10
+		clientToken := c.Request.Header.Get("token")
+		claims, msg := helper.ValidateToken(clientToken)
+		if msg != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		if err := helper.RevokeToken(claims.Id, claims.Uid, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			log.Panic(err)
 		}
-		page, err1 := strconv.Atoi(c.Query("page"))
-		if err1 != nil || page < 1 {
-			page = 1
+
+		var foundUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": claims.Uid}).Decode(&foundUser); err == nil && foundUser.Refresh_Token != nil {
+			if refreshClaims, msg := helper.ValidateToken(*foundUser.Refresh_Token); msg == "" {
+				if err := helper.RevokeToken(refreshClaims.Id, claims.Uid, time.Unix(refreshClaims.ExpiresAt, 0)); err != nil {
+					log.Panic(err)
+				}
+			}
+		}
+
+		_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": claims.Uid}, bson.M{"$set": bson.M{
+			"token":         "",
+			"refresh_token": "",
+		}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error signing out"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Signed out"})
+	}
+}
+
+// RequestPasswordResetInput is the body accepted by RequestPasswordReset.
+type RequestPasswordResetInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// RequestPasswordReset issues a time-boxed reset token and emails it to the
+// address on file. It always returns 200 with the same message, whether or
+// not the email belongs to an account, so callers can't enumerate users.
+func RequestPasswordReset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var input RequestPasswordResetInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		startIndex, err2 := strconv.Atoi(c.Query("startIndex"))
-		if err2 != nil {
-			startIndex = (page - 1) * recordPerPage
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
 		}
 
-		matchStage := bson.D{primitive.E{Key: "$match", Value: bson.D{{}}}}
-		groupStage := bson.D{primitive.E{Key: "$group", Value: bson.D{
-			primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "_id", Value: "null"}}},
-			primitive.E{Key: "total_count", Value: bson.D{primitive.E{Key: "$sum", Value: 1}}},
-			primitive.E{Key: "data", Value: bson.D{primitive.E{Key: "$push", Value: "$$ROOT"}}},
-		}}}
+		const genericResponse = "If an account with that email exists, a reset link has been sent"
+
+		var foundUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"email": input.Email}).Decode(&foundUser); err != nil {
+			c.JSON(http.StatusOK, gin.H{"message": genericResponse})
+			return
+		}
 
-		projectStage := bson.D{
-			primitive.E{Key: "$project", Value: bson.D{
-				primitive.E{Key: "_id", Value: 0},
-				primitive.E{Key: "total_count", Value: 1},
-				primitive.E{Key: "user_items", Value: bson.D{primitive.E{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}},
-			}},
+		token, tokenHash, err := generateResetToken()
+		if err != nil {
+			log.Panic(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error requesting password reset"})
+			return
 		}
 
-		result, err := userCollection.Aggregate(ctx, mongo.Pipeline{
-			matchStage, groupStage, projectStage,
+		_, err = passwordResetCollection.InsertOne(ctx, models.PasswordReset{
+			ID:         primitive.NewObjectID(),
+			User_ID:    foundUser.User_ID,
+			Token_Hash: tokenHash,
+			Expires_At: time.Now().Add(passwordResetTokenTTL()),
+			Used:       false,
+			Created_At: time.Now(),
 		})
+		if err != nil {
+			log.Panic(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error requesting password reset"})
+			return
+		}
+
+		if err := mailer.SendPasswordReset(*foundUser.Email, token); err != nil {
+			log.Println("failed to send password reset email:", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": genericResponse})
+	}
+}
+
+// ResetPasswordInput is the body accepted by ResetPassword.
+type ResetPasswordInput struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// ResetPassword redeems a RequestPasswordReset token and sets a new password.
+func ResetPassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var input ResetPasswordInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
 
+		sum := sha256.Sum256([]byte(input.Token))
+		tokenHash := hex.EncodeToString(sum[:])
+
+		var reset models.PasswordReset
+		err := passwordResetCollection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&reset)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+
+		if reset.Used || time.Now().After(reset.Expires_At) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+
+		newPassword := HashPassword(input.NewPassword)
+		_, err = userCollection.UpdateOne(ctx, bson.M{"user_id": reset.User_ID}, bson.M{"$set": bson.M{
+			"password":   newPassword,
+			"updated_at": time.Now(),
+		}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error resetting password"})
+			return
+		}
+
+		_, err = passwordResetCollection.UpdateOne(ctx, bson.M{"_id": reset.ID}, bson.M{"$set": bson.M{"used": true}})
+		if err != nil {
+			log.Panic(err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+	}
+}
+
+// ChangePasswordInput is the body accepted by ChangePassword.
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// ChangePassword lets an authenticated user change their password after
+// re-proving they know the current one.
+func ChangePassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
+
+		userId := c.GetString("uid")
+
+		var input ChangePasswordInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(input); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		var foundUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&foundUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			return
+		}
+
+		passwordIsValid, msg := VerifyPassword(input.CurrentPassword, *foundUser.Password)
+		if !passwordIsValid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		newPassword := HashPassword(input.NewPassword)
+		_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": bson.M{
+			"password":   newPassword,
+			"updated_at": time.Now(),
+		}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error changing password"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+	}
+}
+
+// recordFailedLogin bumps the user's failed_login_count and, once it reaches
+// maxFailedLoginAttempts, sets locked_until so SignIn rejects further attempts
+// until the lockout window elapses.
+func recordFailedLogin(ctx context.Context, userId string, currentCount int) {
+	newCount := currentCount + 1
+	update := bson.M{"failed_login_count": newCount}
+
+	if newCount >= maxFailedLoginAttempts() {
+		update["locked_until"] = time.Now().Add(accountLockoutDuration())
+		update["failed_login_count"] = 0
+	}
+
+	_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": update})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// resetFailedLogins clears the lockout state after a successful VerifyPassword.
+func resetFailedLogins(ctx context.Context, userId string) {
+	_, err := userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": bson.M{
+		"failed_login_count": 0,
+		"locked_until":        time.Time{},
+	}})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// userListSortableFields allowlists which fields ?sort= and the cursor may
+// key off of, both to keep the cursor's type handling tractable and to avoid
+// ever turning a query param straight into a Mongo field name.
+var userListSortableFields = map[string]bool{
+	"created_at":         true,
+	"updated_at":         true,
+	"name":               true,
+	"email":              true,
+	"username":           true,
+	"user_type":          true,
+	"failed_login_count": true,
+}
+
+// userListCursor identifies the last row of a page - the field being sorted
+// on, that row's value for it, and its _id as a tiebreaker - so the next page
+// can ask for "whatever comes after this". Encoded as
+// base64(json({field, value, id})).
+type userListCursor struct {
+	Field string             `json:"field"`
+	Value interface{}        `json:"value"`
+	ID    primitive.ObjectID `json:"id"`
+}
+
+// userSortFieldValue extracts user's value for field, for encoding into a
+// cursor. field is assumed to already be validated against
+// userListSortableFields.
+func userSortFieldValue(user models.User, field string) interface{} {
+	switch field {
+	case "created_at":
+		return user.Created_at
+	case "updated_at":
+		return user.Updated_at
+	case "name":
+		if user.Name == nil {
+			return ""
+		}
+		return *user.Name
+	case "email":
+		if user.Email == nil {
+			return ""
+		}
+		return *user.Email
+	case "username":
+		if user.Username == nil {
+			return ""
+		}
+		return *user.Username
+	case "user_type":
+		if user.User_Type == nil {
+			return ""
+		}
+		return *user.User_Type
+	case "failed_login_count":
+		return user.Failed_Login_Count
+	default:
+		return nil
+	}
+}
+
+func encodeUserListCursor(field string, value interface{}, id primitive.ObjectID) (string, error) {
+	raw, err := json.Marshal(userListCursor{Field: field, Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(raw), nil
+}
+
+// decodeUserListCursor parses a cursor and coerces its value back into the
+// type Mongo expects for that field - json.Unmarshal into interface{} leaves
+// times and numbers as strings/float64, which would never match a BSON
+// datetime or int field in a comparison.
+func decodeUserListCursor(encoded string) (userListCursor, error) {
+	raw, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return userListCursor{}, err
+	}
+
+	var cursor userListCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return userListCursor{}, err
+	}
+
+	if !userListSortableFields[cursor.Field] {
+		return userListCursor{}, errors.New("unsupported cursor field")
+	}
+
+	switch cursor.Field {
+	case "created_at", "updated_at":
+		s, ok := cursor.Value.(string)
+		if !ok {
+			return userListCursor{}, errors.New("malformed cursor value")
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return userListCursor{}, err
+		}
+		cursor.Value = parsed
+	case "failed_login_count":
+		n, ok := cursor.Value.(float64)
+		if !ok {
+			return userListCursor{}, errors.New("malformed cursor value")
+		}
+		cursor.Value = int(n)
+	}
+
+	return cursor, nil
+}
+
+// parseUserListSort turns "field:asc|desc" into a Mongo sort direction,
+// defaulting to created_at ascending (the field the cursor is anchored to).
+// ok is false when field isn't in userListSortableFields.
+func parseUserListSort(raw string) (field string, dir int, ok bool) {
+	field, dir = "created_at", 1
+
+	if raw == "" {
+		return field, dir, true
+	}
+
+	parts := strings.SplitN(raw, ":", 2)
+	field = parts[0]
+	if len(parts) == 2 && parts[1] == "desc" {
+		dir = -1
+	}
+	return field, dir, userListSortableFields[field]
+}
+
+// GetUsers lists users with optional search/filter params and cursor
+// pagination, returning {items, next_cursor, total}. `total` is only computed
+// when ?with_total=true is set, since CountDocuments is a second full scan.
+// Access is gated by the "users:read" permission via middleware.RequirePermission,
+// applied where this handler is registered.
+func GetUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		limit, err := strconv.Atoi(c.Query("limit"))
+		if err != nil || limit < 1 || limit > 100 {
+			limit = 10
+		}
+
+		sortField, sortDir, sortOk := parseUserListSort(c.Query("sort"))
+		if !sortOk {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported sort field: " + sortField})
+			return
+		}
+
+		conditions := []bson.M{}
+
+		if search := c.Query("search"); search != "" {
+			pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+			conditions = append(conditions, bson.M{"$or": []bson.M{
+				{"name": pattern},
+				{"email": pattern},
+			}})
+		}
+
+		if userType := c.Query("user_type"); userType != "" {
+			conditions = append(conditions, bson.M{"user_type": userType})
+		}
+
+		if createdAfter := c.Query("created_after"); createdAfter != "" {
+			parsed, err := time.Parse(time.RFC3339, createdAfter)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "created_after must be an RFC3339 timestamp"})
+				return
+			}
+			conditions = append(conditions, bson.M{"created_at": bson.M{"$gt": parsed}})
+		}
+
+		if after := c.Query("after"); after != "" {
+			cursor, err := decodeUserListCursor(after)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+				return
+			}
+
+			if cursor.Field != sortField {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cursor does not match the current sort field"})
+				return
+			}
+
+			cmpOp := "$gt"
+			if sortDir == -1 {
+				cmpOp = "$lt"
+			}
+
+			conditions = append(conditions, bson.M{"$or": []bson.M{
+				{sortField: bson.M{cmpOp: cursor.Value}},
+				{sortField: cursor.Value, "_id": bson.M{cmpOp: cursor.ID}},
+			}})
+		}
+
+		matchStage := bson.M{}
+		if len(conditions) > 0 {
+			matchStage["$and"] = conditions
+		}
+
+		findOptions := options.Find().
+			SetSort(bson.D{{Key: sortField, Value: sortDir}, {Key: "_id", Value: sortDir}}).
+			SetLimit(int64(limit))
+
+		cursor, err := userCollection.Find(ctx, matchStage, findOptions)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while listing users..."})
+			return
+		}
+
+		var users []models.User
+		if err = cursor.All(ctx, &users); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while listing users..."})
+			return
+		}
+
+		response := gin.H{"items": users, "next_cursor": nil}
+
+		if len(users) == int(limit) {
+			last := users[len(users)-1]
+			nextCursor, err := encodeUserListCursor(sortField, userSortFieldValue(last, sortField), last.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while listing users..."})
+				return
+			}
+			response["next_cursor"] = nextCursor
 		}
 
-		var allUsers []bson.M
-		if err = result.All(ctx, &allUsers); err != nil {
-			log.Fatal(err)
+		if c.Query("with_total") == "true" {
+			total, err := userCollection.CountDocuments(ctx, matchStage)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while counting users..."})
+				return
+			}
+			response["total"] = total
 		}
 
-		c.JSON(http.StatusOK, allUsers[0])
+		c.JSON(http.StatusOK, response)
 	}
 }
 
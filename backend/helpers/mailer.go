@@ -0,0 +1,38 @@
+package helper
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+)
+
+// Mailer is the pluggable send path for transactional email. Swapping in a
+// test double avoids real SMTP calls in unit tests.
+type Mailer interface {
+	SendPasswordReset(toEmail string, resetToken string) error
+}
+
+// SMTPMailer sends mail through a standard SMTP relay configured via env vars
+// (SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, SMTP_FROM).
+type SMTPMailer struct{}
+
+func NewSMTPMailer() *SMTPMailer {
+	return &SMTPMailer{}
+}
+
+func (m *SMTPMailer) SendPasswordReset(toEmail string, resetToken string) error {
+	host := os.Getenv("SMTP_HOST")
+	port := os.Getenv("SMTP_PORT")
+	username := os.Getenv("SMTP_USERNAME")
+	password := os.Getenv("SMTP_PASSWORD")
+	from := os.Getenv("SMTP_FROM")
+
+	addr := fmt.Sprintf("%s:%s", host, port)
+	auth := smtp.PlainAuth("", username, password, host)
+
+	subject := "Subject: Reset your password\r\n"
+	body := fmt.Sprintf("Use this token to reset your password: %s\r\n", resetToken)
+	msg := []byte(subject + "\r\n" + body)
+
+	return smtp.SendMail(addr, auth, from, []string{toEmail}, msg)
+}
@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"context"
+	"time"
+
+	"budget-app/backend/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var roleCollection *mongo.Collection = database.OpenCollection(database.Client, "roles")
+
+// ResolvePermissions unions the permission sets of the given role names so
+// they can be embedded in a user's JWT at issuance (see GenerateAllTokens).
+func ResolvePermissions(roleNames []string) ([]string, error) {
+	permissions := []string{}
+	if len(roleNames) == 0 {
+		return permissions, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	cursor, err := roleCollection.Find(ctx, bson.M{"name": bson.M{"$in": roleNames}})
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []struct {
+		Permissions []string `bson:"permissions"`
+	}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
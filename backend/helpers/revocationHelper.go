@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"context"
+	"time"
+
+	"budget-app/backend/database"
+	"budget-app/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var revokedTokenCollection *mongo.Collection = database.OpenCollection(database.Client, "revoked_tokens")
+
+// RevokeToken records jti as unusable until expiresAt, at which point it would
+// have expired naturally anyway and can be allowed to age out of the collection.
+func RevokeToken(jti string, userId string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	_, err := revokedTokenCollection.InsertOne(ctx, models.RevokedToken{
+		ID:         primitive.NewObjectID(),
+		Jti:        jti,
+		User_ID:    userId,
+		Expires_At: expiresAt,
+		Revoked_At: time.Now(),
+	})
+	return err
+}
+
+// IsTokenRevoked reports whether jti has been explicitly invalidated, i.e. by
+// refresh-token rotation, SignOut, or redemption of an MFA challenge token.
+// No handler revokes another user's tokens.
+func IsTokenRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	count, err := revokedTokenCollection.CountDocuments(ctx, bson.M{"jti": jti})
+	if err != nil {
+		return false
+	}
+	return count > 0
+}
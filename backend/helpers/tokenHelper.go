@@ -0,0 +1,139 @@
+package helper
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"budget-app/backend/database"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SignedDetails struct {
+	Email       string
+	Name        string
+	Uid         string
+	User_Type   string
+	Roles       []string
+	Permissions []string
+	// MFA marks this as a short-lived challenge token issued by SignIn when
+	// the account has TOTP enabled - it carries no roles/permissions and
+	// Authenticate rejects it outright, so it's only useful for redeeming via
+	// SignInVerifyTOTP/SignInVerifyRecovery.
+	MFA bool
+	jwt.StandardClaims
+}
+
+var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
+var SECRET_KEY = os.Getenv("SECRET_KEY")
+
+// GenerateAllTokens issues a signed access+refresh pair. roles/permissions
+// are embedded in the access token's claims so RequirePermission and
+// RequireRole can authorize a request without a database round trip.
+func GenerateAllTokens(email string, name string, userType string, uid string, roles []string, permissions []string) (signedToken string, signedRefreshToken string, err error) {
+	claims := &SignedDetails{
+		Email:       email,
+		Name:        name,
+		Uid:         uid,
+		User_Type:   userType,
+		Roles:       roles,
+		Permissions: permissions,
+		StandardClaims: jwt.StandardClaims{
+			Id:        primitive.NewObjectID().Hex(),
+			ExpiresAt: time.Now().Local().Add(time.Hour * 24).Unix(),
+		},
+	}
+
+	refreshClaims := &SignedDetails{
+		Uid: uid,
+		StandardClaims: jwt.StandardClaims{
+			Id:        primitive.NewObjectID().Hex(),
+			ExpiresAt: time.Now().Local().Add(time.Hour * 24 * 7).Unix(),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(SECRET_KEY))
+	if err != nil {
+		log.Panic(err)
+		return
+	}
+
+	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(SECRET_KEY))
+	if err != nil {
+		log.Panic(err)
+		return
+	}
+
+	return token, refreshToken, err
+}
+
+// GenerateMFAChallengeToken issues a short-lived token proving the caller
+// already supplied a correct password, to be redeemed by
+// SignInVerifyTOTP/SignInVerifyRecovery along with a second factor.
+func GenerateMFAChallengeToken(uid string) (string, error) {
+	claims := &SignedDetails{
+		Uid: uid,
+		MFA: true,
+		StandardClaims: jwt.StandardClaims{
+			Id:        primitive.NewObjectID().Hex(),
+			ExpiresAt: time.Now().Local().Add(5 * time.Minute).Unix(),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(SECRET_KEY))
+}
+
+// ValidateToken parses and verifies a signed JWT, returning the embedded
+// claims or an error message suitable for returning straight to the client.
+func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
+	token, err := jwt.ParseWithClaims(
+		signedToken,
+		&SignedDetails{},
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(SECRET_KEY), nil
+		},
+	)
+	if err != nil {
+		msg = err.Error()
+		return
+	}
+
+	claims, ok := token.Claims.(*SignedDetails)
+	if !ok {
+		msg = "the token is invalid"
+		return
+	}
+
+	if claims.ExpiresAt < time.Now().Local().Unix() {
+		msg = "token is expired"
+		return
+	}
+
+	return claims, msg
+}
+
+func UpdateAllTokens(signedToken string, signedRefreshToken string, userId string) {
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+
+	var updateObj bson.D
+	updateObj = append(updateObj, bson.E{Key: "token", Value: signedToken})
+	updateObj = append(updateObj, bson.E{Key: "refresh_token", Value: signedRefreshToken})
+	updateObj = append(updateObj, bson.E{Key: "updated_at", Value: time.Now()})
+
+	upsert := true
+	filter := bson.M{"user_id": userId}
+	opt := mongo.UpdateOptions{Upsert: &upsert}
+
+	_, err := userCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}, &opt)
+	defer cancel()
+
+	if err != nil {
+		log.Panic(err)
+		return
+	}
+}
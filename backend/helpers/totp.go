@@ -0,0 +1,165 @@
+package helper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	totpStepSeconds     = 30
+	totpDigits          = 6
+	totpWindowTolerance = 1 // allow the previous/next step either side of now
+)
+
+// GenerateTOTPSecret returns a new random base32 secret suitable for seeding
+// an authenticator app (e.g. via an otpauth:// QR code).
+func GenerateTOTPSecret() (string, error) {
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+// totpCodeAt computes the RFC 6238 TOTP code for secret at the given 30s
+// time step counter.
+func totpCodeAt(secret string, counter uint64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(buf)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, truncated%mod), nil
+}
+
+// ValidateTOTPCode checks code against secret, tolerating clock drift of one
+// 30s step either side of the current time per RFC 6238.
+func ValidateTOTPCode(secret string, code string) bool {
+	now := time.Now().Unix() / totpStepSeconds
+
+	for offset := -totpWindowTolerance; offset <= totpWindowTolerance; offset++ {
+		expected, err := totpCodeAt(secret, uint64(now+int64(offset)))
+		if err != nil {
+			return false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateRecoveryCodes returns n single-use plaintext recovery codes. The
+// caller is responsible for bcrypt-hashing them before persisting - these are
+// shown to the user exactly once, at enrollment.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		codes[i] = strings.ToUpper(hex.EncodeToString(raw))
+	}
+	return codes, nil
+}
+
+// totpEncryptionKey loads the 32-byte AES-256 key used to encrypt TOTP
+// secrets at rest, from the hex-encoded TOTP_ENCRYPTION_KEY env var.
+func totpEncryptionKey() ([]byte, error) {
+	key, err := hex.DecodeString(os.Getenv("TOTP_ENCRYPTION_KEY"))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY must be a hex-encoded 32-byte key")
+	}
+	return key, nil
+}
+
+// EncryptTOTPSecret encrypts a TOTP secret with AES-256-GCM before it is
+// persisted, so a database dump alone can't be used to generate codes.
+func EncryptTOTPSecret(secret string) (string, error) {
+	key, err := totpEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptTOTPSecret reverses EncryptTOTPSecret.
+func DecryptTOTPSecret(encrypted string) (string, error) {
+	key, err := totpEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(raw) < nonceSize {
+		return "", errors.New("malformed ciphertext")
+	}
+
+	nonce, ciphertext := raw[:nonceSize], raw[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
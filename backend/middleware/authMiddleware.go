@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	helper "budget-app/backend/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate verifies the bearer token on every protected request, rejecting
+// it outright if it has expired or if its jti has been revoked.
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientToken := c.Request.Header.Get("token")
+		if clientToken == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "No Authorization header provided"})
+			c.Abort()
+			return
+		}
+
+		claims, msg := helper.ValidateToken(clientToken)
+		if msg != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": msg})
+			c.Abort()
+			return
+		}
+
+		if helper.IsTokenRevoked(claims.Id) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		if claims.MFA {
+			c.JSON(http.StatusForbidden, gin.H{"error": "MFA challenge not yet completed"})
+			c.Abort()
+			return
+		}
+
+		c.Set("email", claims.Email)
+		c.Set("name", claims.Name)
+		c.Set("uid", claims.Uid)
+		c.Set("user_type", claims.User_Type)
+		c.Set("roles", claims.Roles)
+		c.Set("permissions", claims.Permissions)
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission allows the request through only if the caller's JWT
+// claims include the given permission. Permissions are embedded at token
+// issuance (see helper.GenerateAllTokens), so this never needs a DB hit.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, _ := c.Get("permissions")
+		granted, _ := permissions.([]string)
+
+		for _, p := range granted {
+			if p == permission {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "missing required permission: " + permission})
+		c.Abort()
+	}
+}
+
+// RequireRole allows the request through only if the caller holds the given
+// role.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, _ := c.Get("roles")
+		held, _ := roles.([]string)
+
+		for _, r := range held {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "missing required role: " + role})
+		c.Abort()
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordReset is a single-use, time-boxed token issued by
+// RequestPasswordReset and redeemed by ResetPassword. Only the hash of the
+// token is stored so a leaked database dump can't be used to reset accounts.
+type PasswordReset struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	User_ID    string             `json:"user_id" bson:"user_id"`
+	Token_Hash string             `json:"token_hash" bson:"token_hash"`
+	Expires_At time.Time          `json:"expires_at" bson:"expires_at"`
+	Used       bool               `json:"used" bson:"used"`
+	Created_At time.Time          `json:"created_at" bson:"created_at"`
+}
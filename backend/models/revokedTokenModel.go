@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevokedToken marks a token's jti as unusable before its natural expiry,
+// because it was rotated out by RefreshToken, signed out via SignOut, or
+// consumed as an MFA challenge token. No handler revokes another user's tokens.
+type RevokedToken struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Jti        string             `json:"jti" bson:"jti"`
+	User_ID    string             `json:"user_id" bson:"user_id"`
+	Expires_At time.Time          `json:"expires_at" bson:"expires_at"`
+	Revoked_At time.Time          `json:"revoked_at" bson:"revoked_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Role is a named bundle of permissions that can be assigned to users via
+// AssignRole. The resulting permission set is embedded directly in a user's
+// JWT at issuance so RequirePermission can authorize requests without a
+// database round trip.
+type Role struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Name        string             `json:"name" bson:"name" validate:"required"`
+	Permissions []string           `json:"permissions" bson:"permissions"`
+}
+
+// Permission is a colon-namespaced capability string, e.g. "users:read".
+type Permission = string
+
+const (
+	PermissionUsersRead   Permission = "users:read"
+	PermissionUsersWrite  Permission = "users:write"
+	PermissionRolesManage Permission = "roles:manage"
+)
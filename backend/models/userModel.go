@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type User struct {
+	ID                 primitive.ObjectID `bson:"_id"`
+	Name               *string            `json:"name" validate:"required,min=2,max=100"`
+	Username           *string            `json:"username" validate:"required,min=2,max=100"`
+	Password           *string            `json:"password" validate:"required,min=6"`
+	Email              *string            `json:"email" validate:"email,required"`
+	Token              *string            `json:"token"`
+	User_Type          *string            `json:"user_type" validate:"required,eq=ADMIN|eq=USER"`
+	Refresh_Token      *string            `json:"refresh_token"`
+	Failed_Login_Count int                `json:"failed_login_count" bson:"failed_login_count"`
+	Locked_Until       time.Time          `json:"locked_until" bson:"locked_until"`
+	Roles              []string           `json:"roles" bson:"roles"`
+	TOTP_Secret        string             `json:"-" bson:"totp_secret"`
+	TOTP_Enabled       bool               `json:"totp_enabled" bson:"totp_enabled"`
+	Recovery_Codes     []string           `json:"-" bson:"recovery_codes"`
+	Created_at         time.Time          `json:"created_at" bson:"created_at"`
+	Updated_at         time.Time          `json:"updated_at" bson:"updated_at"`
+	User_ID            string             `json:"user_id"`
+}
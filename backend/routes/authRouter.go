@@ -0,0 +1,20 @@
+package routes
+
+import (
+	controller "budget-app/backend/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthRoutes wires up the unauthenticated auth flows: account creation,
+// sign-in (plus MFA challenge redemption), refresh-token redemption and
+// password reset.
+func AuthRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.POST("/users/signup", controller.SignUp())
+	incomingRoutes.POST("/users/signin", controller.SignIn())
+	incomingRoutes.POST("/users/signin/verify-totp", controller.SignInVerifyTOTP())
+	incomingRoutes.POST("/users/signin/verify-recovery-code", controller.SignInVerifyRecovery())
+	incomingRoutes.POST("/users/refresh-token", controller.RefreshToken())
+	incomingRoutes.POST("/users/password-reset", controller.RequestPasswordReset())
+	incomingRoutes.POST("/users/password-reset/confirm", controller.ResetPassword())
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	controller "budget-app/backend/controllers"
+	"budget-app/backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserRoutes wires up the routes that require a valid, non-revoked access
+// token.
+func UserRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.Use(middleware.Authenticate())
+
+	incomingRoutes.POST("/users/signout", controller.SignOut())
+	incomingRoutes.POST("/users/change-password", controller.ChangePassword())
+	incomingRoutes.GET("/users", middleware.RequirePermission("users:read"), controller.GetUsers())
+	incomingRoutes.GET("/users/:user_id", controller.GetUser())
+
+	incomingRoutes.POST("/users/totp/enroll", controller.EnrollTOTP())
+	incomingRoutes.POST("/users/totp/confirm", controller.ConfirmTOTP())
+	incomingRoutes.POST("/users/totp/disable", controller.DisableTOTP())
+
+	incomingRoutes.GET("/roles", middleware.RequirePermission("roles:manage"), controller.ListRoles())
+	incomingRoutes.POST("/roles", middleware.RequirePermission("roles:manage"), controller.CreateRole())
+	incomingRoutes.POST("/roles/assign", middleware.RequirePermission("roles:manage"), controller.AssignRole())
+	incomingRoutes.POST("/roles/revoke", middleware.RequirePermission("roles:manage"), controller.RevokeRole())
+}